@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLS configures automatic certificate issuance and renewal via ACME
+// (Let's Encrypt by default), as an alternative to a static Cert/Key pair.
+type AutoTLS struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Hosts    []string `mapstructure:"hosts"`
+	CacheDir string   `mapstructure:"cache-dir"`
+	Email    string   `mapstructure:"email"`
+	Staging  bool     `mapstructure:"staging"`
+	// HTTPBind is the address the HTTP-01 challenge responder listens on.
+	// Defaults to ":80".
+	HTTPBind string `mapstructure:"http-bind"`
+}
+
+func (s *Server) autocertManager() *autocert.Manager {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(s.AutoTLS.Hosts...),
+		Cache:      autocert.DirCache(s.AutoTLS.CacheDir),
+		Email:      s.AutoTLS.Email,
+	}
+
+	if s.AutoTLS.Staging {
+		manager.Client = &acme.Client{DirectoryURL: "https://acme-staging-v02.api.letsencrypt.org/directory"}
+	}
+
+	return manager
+}
+
+// ListenAndServeTLS serves handler over HTTPS on s.Bind. When AutoTLS is
+// enabled, certificates are obtained and renewed automatically via ACME,
+// answering HTTP-01 challenges on AutoTLS.HTTPBind; otherwise it falls back
+// to the static Cert/Key pair, letting users run go-transcode directly on
+// the edge without fronting it with nginx/caddy for TLS.
+func (s *Server) ListenAndServeTLS(handler http.Handler) error {
+	if s.AutoTLS.Enabled {
+		manager := s.autocertManager()
+
+		go func() {
+			if err := http.ListenAndServe(s.AutoTLS.HTTPBind, manager.HTTPHandler(nil)); err != nil {
+				log.Error().Err(err).Str("bind", s.AutoTLS.HTTPBind).Msg("autotls: HTTP-01 challenge listener failed, certificates will not renew")
+			}
+		}()
+
+		server := &http.Server{
+			Addr:      s.Bind,
+			Handler:   handler,
+			TLSConfig: manager.TLSConfig(),
+		}
+		return server.ListenAndServeTLS("", "")
+	}
+
+	if s.Cert == "" || s.Key == "" {
+		return fmt.Errorf("no TLS configuration: set autotls.enabled or cert/key")
+	}
+
+	server := &http.Server{Addr: s.Bind, Handler: handler}
+	return server.ListenAndServeTLS(s.Cert, s.Key)
+}
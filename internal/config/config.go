@@ -1,15 +1,16 @@
 package config
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -18,6 +19,8 @@ type Root struct {
 	Debug   bool
 	PProf   bool
 	CfgFile string
+
+	Logging LoggingConfig
 }
 
 func (Root) Init(cmd *cobra.Command) error {
@@ -43,6 +46,33 @@ func (s *Root) Set() {
 	s.Debug = viper.GetBool("debug")
 	s.PProf = viper.GetBool("pprof")
 	s.CfgFile = viper.GetString("config")
+
+	//
+	// Secrets
+	//
+	var secrets SecretsConfig
+	if err := viper.UnmarshalKey("secrets", &secrets); err != nil {
+		panic(err)
+	}
+
+	if secrets.KeySource != "" {
+		key, err := loadSecretsKey(secrets.KeySource)
+		if err != nil {
+			panic(err)
+		}
+		secretsKey = key
+	}
+
+	//
+	// Logging
+	//
+	if err := viper.UnmarshalKey("logging", &s.Logging); err != nil {
+		panic(err)
+	}
+
+	if err := setupLogging(s.Logging); err != nil {
+		panic(err)
+	}
 }
 
 type VideoProfile struct {
@@ -100,9 +130,122 @@ type Server struct {
 
 	Vod      VOD
 	HlsProxy map[string]string
+
+	// StreamProviders are polled on startup, and then again every
+	// refresh-interval, to keep Streams populated without a restart.
+	StreamProviders []StreamProviderConfig `mapstructure:"stream-providers"`
+
+	AutoTLS AutoTLS
+
+	// mu guards every field above that can change via Reload: Streams,
+	// HlsProxy, Vod.VideoProfiles, Enigma2 and StreamProviders.
+	mu sync.RWMutex
+
+	reloadSubs reloadSubscribers
+
+	// providersMu guards providerCancels, the stop functions of the
+	// currently-running stream provider refresh loops.
+	providersMu     sync.Mutex
+	providerCancels []context.CancelFunc
+}
+
+// mergeStreams safely merges fetched into s.Streams.
+func (s *Server) mergeStreams(fetched map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Streams == nil {
+		s.Streams = map[string]string{}
+	}
+	for name, stream := range fetched {
+		s.Streams[name] = stream
+	}
+}
+
+// GetStreams returns a snapshot of the current streams map, safe to read
+// while Reload may be running concurrently.
+func (s *Server) GetStreams() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	streams := make(map[string]string, len(s.Streams))
+	for name, stream := range s.Streams {
+		streams[name] = stream
+	}
+	return streams
+}
+
+// GetHlsProxy returns a snapshot of the current HLS proxy map.
+func (s *Server) GetHlsProxy() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	proxy := make(map[string]string, len(s.HlsProxy))
+	for name, target := range s.HlsProxy {
+		proxy[name] = target
+	}
+	return proxy
+}
+
+// runStreamProvider refetches and merges provider every refreshEvery, until
+// ctx is cancelled. Reload cancels and replaces these loops wholesale, so a
+// provider removed from stream-providers stops being polled, and a provider
+// whose credentials were rotated picks up the new ones immediately.
+func (s *Server) runStreamProvider(ctx context.Context, provider StreamProvider, refreshEvery time.Duration) {
+	ticker := time.NewTicker(refreshEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			streams, err := provider.Fetch(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("stream provider refresh failed")
+				continue
+			}
+			s.mergeStreams(streams)
+		}
+	}
+}
+
+// startStreamProviders stops whichever provider refresh loops are currently
+// running and starts fresh ones for providers, so the set of running loops
+// (and the credentials they use) always matches the most recently applied
+// config rather than whatever was running when they were first started.
+func (s *Server) startStreamProviders(providers []StreamProviderConfig) error {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+
+	for _, cancel := range s.providerCancels {
+		cancel()
+	}
+	s.providerCancels = nil
+
+	for _, providerCfg := range providers {
+		refreshEvery, err := providerCfg.RefreshEvery()
+		if err != nil {
+			return fmt.Errorf("invalid refresh-interval: %v", err)
+		}
+		if refreshEvery == 0 {
+			continue
+		}
+
+		provider, err := NewStreamProvider(providerCfg)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.providerCancels = append(s.providerCancels, cancel)
+		go s.runStreamProvider(ctx, provider, refreshEvery)
+	}
+
+	return nil
 }
 
-func (Server) Init(cmd *cobra.Command) error {
+func (*Server) Init(cmd *cobra.Command) error {
 	cmd.PersistentFlags().String("bind", "127.0.0.1:8080", "address/port/socket to serve neko")
 	if err := viper.BindPFlag("bind", cmd.PersistentFlags().Lookup("bind")); err != nil {
 		return err
@@ -148,6 +291,28 @@ func (s *Server) Set() {
 	s.Static = viper.GetString("static")
 	s.Proxy = viper.GetBool("proxy")
 
+	//
+	// AutoTLS
+	//
+	if err := viper.UnmarshalKey("autotls", &s.AutoTLS); err != nil {
+		panic(err)
+	}
+
+	if s.AutoTLS.Enabled {
+		if s.Cert != "" || s.Key != "" {
+			panic("autotls and cert/key are mutually exclusive, configure only one")
+		}
+		if len(s.AutoTLS.Hosts) == 0 {
+			panic("autotls.hosts must list at least one host")
+		}
+		if s.AutoTLS.CacheDir == "" {
+			panic("autotls.cache-dir must be set")
+		}
+		if s.AutoTLS.HTTPBind == "" {
+			s.AutoTLS.HTTPBind = ":80"
+		}
+	}
+
 	s.BaseDir = viper.GetString("basedir")
 	if s.BaseDir == "" {
 		if _, err := os.Stat("/etc/transcode"); os.IsNotExist(err) {
@@ -210,43 +375,84 @@ func (s *Server) Set() {
 	// HLS PROXY
 	//
 	s.HlsProxy = viper.GetStringMapString("hls-proxy")
+	for name, target := range s.HlsProxy {
+		decrypted, err := decryptIfNeeded(target)
+		if err != nil {
+			panic(err)
+		}
+		s.HlsProxy[name] = decrypted
+	}
 
 	//
-	// Enigma2
+	// Enigma2 (legacy top-level config, now routed through the same
+	// authenticated StreamProvider path as stream-providers entries)
 	//
 	if err := viper.UnmarshalKey("enigma2", &s.Enigma2); err != nil {
 		panic(err)
 	}
 
-	if s.Enigma2.IP != "" && s.Enigma2.Port != "" {
-		if s.Enigma2.Bouquet == "" {
-			s.Enigma2.Bouquet = "Favourites (TV)"
+	//
+	// Stream providers (enigma2, m3u, xmltv)
+	//
+	if err := viper.UnmarshalKey("stream-providers", &s.StreamProviders); err != nil {
+		panic(err)
+	}
+
+	for i := range s.StreamProviders {
+		if err := s.StreamProviders[i].decryptSecrets(); err != nil {
+			panic(err)
 		}
-		xmlBytes, err := getXML("http://" + s.Enigma2.IP + "/web/getservices")
+	}
+
+	streams, err := fetchConfiguredStreams(s.Enigma2, s.StreamProviders)
+	if err != nil {
+		panic(err)
+	}
+	s.mergeStreams(streams)
+
+	if err := s.startStreamProviders(s.StreamProviders); err != nil {
+		panic(err)
+	}
+}
+
+// fetchConfiguredStreams fetches the legacy top-level `enigma2:` block (if
+// configured) and every `stream-providers` entry, merging their results into
+// a single streams map. Both Set() and Reload() call this so an Enigma2 box
+// configured via either path goes through the same authenticated fetch.
+func fetchConfiguredStreams(enigma2 ENIGMA2, providers []StreamProviderConfig) (map[string]string, error) {
+	streams := map[string]string{}
+
+	if cfg, ok := enigma2.asProviderConfig(); ok {
+		provider, err := NewStreamProvider(cfg)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		var services ServiceList
-		xml.Unmarshal(xmlBytes, &services)
 
-		for i := 0; i < len(services.ServiceList); i++ {
-			if services.ServiceList[i].Name == s.Enigma2.Bouquet {
-				s.Enigma2.Reference = services.ServiceList[i].Reference
-			}
+		fetched, err := provider.Fetch(context.Background())
+		if err != nil {
+			return nil, err
 		}
+		for name, stream := range fetched {
+			streams[name] = stream
+		}
+	}
 
-		if s.Enigma2.Reference != "" {
-			xmlBytes, err := getXML("http://" + s.Enigma2.IP + "/web/getservices?sRef=" + url.QueryEscape(s.Enigma2.Reference))
-			if err != nil {
-				panic(err)
-			}
-			var channels ServiceList
-			xml.Unmarshal(xmlBytes, &channels)
-			for i := 0; i < len(channels.ServiceList); i++ {
-				s.Streams[channelName(channels.ServiceList[i].Name)] = "http://" + s.Enigma2.IP + ":" + s.Enigma2.Port + "/" + channels.ServiceList[i].Reference
-			}
+	for _, providerCfg := range providers {
+		provider, err := NewStreamProvider(providerCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		fetched, err := provider.Fetch(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for name, stream := range fetched {
+			streams[name] = stream
 		}
 	}
+
+	return streams, nil
 }
 
 func (s *Server) AbsPath(elem ...string) string {
@@ -255,25 +461,6 @@ func (s *Server) AbsPath(elem ...string) string {
 	return path.Join(elem...)
 }
 
-func getXML(url string) ([]byte, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return []byte{}, fmt.Errorf("GET error: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return []byte{}, fmt.Errorf("Status error: %v", resp.StatusCode)
-	}
-
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, fmt.Errorf("Read body: %v", err)
-	}
-
-	return data, nil
-}
-
 func channelName(name string) string {
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, " ", "_")
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/diode"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// LoggingConfig configures rotated file logging. When File is empty,
+// console logging to stderr is kept as the default.
+type LoggingConfig struct {
+	File       string `mapstructure:"file"`
+	Dir        string `mapstructure:"dir"`
+	MaxSizeMB  int    `mapstructure:"max-size-mb"`
+	MaxBackups int    `mapstructure:"max-backups"`
+	MaxAgeDays int    `mapstructure:"max-age-days"`
+	Compress   bool   `mapstructure:"compress"`
+	Async      bool   `mapstructure:"async"`
+	BufferSize int    `mapstructure:"buffer-size"`
+}
+
+// setupLogging installs the global zerolog logger according to cfg. With no
+// file configured, logs keep going to the console. Otherwise, whatever was
+// left at cfg.File from a previous run is rotated out to a timestamped file
+// first, so operators always find a clean, current log at cfg.File while
+// history is retained alongside it.
+func setupLogging(cfg LoggingConfig) error {
+	if cfg.File == "" {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+		return nil
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("logging: create dir: %v", err)
+	}
+
+	logPath := path.Join(dir, cfg.File)
+
+	if info, err := os.Stat(logPath); err == nil && !info.IsDir() {
+		rotated := fmt.Sprintf("%s.%s", logPath, time.Now().Format("20060102-150405"))
+		if err := os.Rename(logPath, rotated); err != nil {
+			return fmt.Errorf("logging: rotate previous log: %v", err)
+		}
+	}
+
+	var writer io.Writer = &lumberjack.Logger{
+		Filename:   logPath,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+
+	if cfg.Async {
+		bufferSize := cfg.BufferSize
+		if bufferSize == 0 {
+			bufferSize = 1000
+		}
+		writer = diode.NewWriter(writer, bufferSize, 10*time.Millisecond, func(missed int) {
+			fmt.Fprintf(os.Stderr, "logging: dropped %d messages\n", missed)
+		})
+	}
+
+	log.Logger = log.Output(writer)
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"encoding/xml"
+	"net/url"
+)
+
+// enigma2Provider fetches the channel list of a bouquet from an Enigma2
+// (OpenWebif) set-top box, optionally authenticated and over HTTPS.
+type enigma2Provider struct {
+	cfg StreamProviderConfig
+}
+
+// asProviderConfig adapts the legacy top-level `enigma2:` block into a
+// StreamProviderConfig, so it goes through the same authenticated
+// StreamProvider path as `stream-providers` entries, rather than a separate
+// unauthenticated http.Get.
+func (e ENIGMA2) asProviderConfig() (StreamProviderConfig, bool) {
+	if e.IP == "" || e.Port == "" {
+		return StreamProviderConfig{}, false
+	}
+
+	return StreamProviderConfig{
+		Type:    "enigma2",
+		IP:      e.IP,
+		Port:    e.Port,
+		Bouquet: e.Bouquet,
+	}, true
+}
+
+func (p *enigma2Provider) servicesURL() string {
+	if p.cfg.URL != "" {
+		return p.cfg.URL
+	}
+	return "http://" + p.cfg.IP + ":" + p.cfg.Port + "/web/getservices"
+}
+
+func (p *enigma2Provider) Fetch(ctx context.Context) (map[string]string, error) {
+	bouquet := p.cfg.Bouquet
+	if bouquet == "" {
+		bouquet = "Favourites (TV)"
+	}
+
+	bouquetsXML, err := p.cfg.get(ctx, p.servicesURL())
+	if err != nil {
+		return nil, err
+	}
+
+	var bouquets ServiceList
+	if err := xml.Unmarshal(bouquetsXML, &bouquets); err != nil {
+		return nil, err
+	}
+
+	var reference string
+	for _, service := range bouquets.ServiceList {
+		if service.Name == bouquet {
+			reference = service.Reference
+			break
+		}
+	}
+
+	streams := map[string]string{}
+	if reference == "" {
+		return streams, nil
+	}
+
+	channelsXML, err := p.cfg.get(ctx, p.servicesURL()+"?sRef="+url.QueryEscape(reference))
+	if err != nil {
+		return nil, err
+	}
+
+	var channels ServiceList
+	if err := xml.Unmarshal(channelsXML, &channels); err != nil {
+		return nil, err
+	}
+
+	for _, channel := range channels.ServiceList {
+		streams[channelName(channel.Name)] = "http://" + p.cfg.IP + ":" + p.cfg.Port + "/" + channel.Reference
+	}
+
+	return streams, nil
+}
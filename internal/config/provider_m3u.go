@@ -0,0 +1,54 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+)
+
+// m3uProvider parses an IPTV M3U/M3U8 playlist, pairing each #EXTINF entry
+// with the stream URL on the following line.
+type m3uProvider struct {
+	cfg StreamProviderConfig
+}
+
+// m3uNameRegexp anchors on the last comma in the line: tvg-name/group-title
+// attributes before it routinely contain their own commas (e.g.
+// `tvg-name="A, B"`), so matching the first comma garbles the channel name.
+var m3uNameRegexp = regexp.MustCompile(`,([^,]*)$`)
+
+func (p *m3uProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	data, err := p.cfg.get(ctx, p.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := map[string]string{}
+
+	var pending string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line == "#EXTM3U":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			if match := m3uNameRegexp.FindStringSubmatch(line); match != nil {
+				pending = channelName(strings.TrimSpace(match[1]))
+			} else {
+				pending = ""
+			}
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			if pending != "" {
+				streams[pending] = line
+				pending = ""
+			}
+		}
+	}
+
+	return streams, scanner.Err()
+}
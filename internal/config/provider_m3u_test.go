@@ -0,0 +1,46 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestM3UProviderFetch(t *testing.T) {
+	const playlist = `#EXTM3U
+#EXTINF:-1,Channel One
+http://example.com/one.m3u8
+#EXTINF:-1 tvg-id="two",Channel Two
+http://example.com/two.m3u8
+#EXTINF:-1 tvg-name="A, B" group-title="News, Sports",Channel Three
+http://example.com/three.m3u8
+`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(playlist))
+	}))
+	defer server.Close()
+
+	provider := &m3uProvider{cfg: StreamProviderConfig{URL: server.URL}}
+
+	streams, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	want := map[string]string{
+		"channel_one":   "http://example.com/one.m3u8",
+		"channel_two":   "http://example.com/two.m3u8",
+		"channel_three": "http://example.com/three.m3u8",
+	}
+
+	if len(streams) != len(want) {
+		t.Fatalf("got %d streams, want %d: %v", len(streams), len(want), streams)
+	}
+	for name, url := range want {
+		if streams[name] != url {
+			t.Errorf("streams[%q] = %q, want %q", name, streams[name], url)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// xmltvChannel is a subset of the XMLTV <channel> element. The `URL` field is
+// a non-standard extension some providers embed alongside the EPG metadata so
+// a channel's stream can be resolved straight from the guide.
+type xmltvChannel struct {
+	ID          string `xml:"id,attr"`
+	DisplayName string `xml:"display-name"`
+	URL         string `xml:"url"`
+}
+
+type xmltvDocument struct {
+	XMLName  xml.Name       `xml:"tv"`
+	Channels []xmltvChannel `xml:"channel"`
+}
+
+// xmltvProvider extracts channel streams from an XMLTV EPG document.
+// Channels without a `<url>` entry are skipped, since plain XMLTV carries no
+// playback information.
+type xmltvProvider struct {
+	cfg StreamProviderConfig
+}
+
+func (p *xmltvProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	data, err := p.cfg.get(ctx, p.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc xmltvDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	streams := map[string]string{}
+	for _, channel := range doc.Channels {
+		if channel.URL == "" {
+			continue
+		}
+
+		name := channel.DisplayName
+		if name == "" {
+			name = channel.ID
+		}
+		streams[channelName(name)] = channel.URL
+	}
+
+	return streams, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestXMLTVProviderFetch(t *testing.T) {
+	const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<tv>
+	<channel id="one.tv">
+		<display-name>Channel One</display-name>
+		<url>http://example.com/one.m3u8</url>
+	</channel>
+	<channel id="two.tv">
+		<display-name>Channel Two</display-name>
+	</channel>
+</tv>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	provider := &xmltvProvider{cfg: StreamProviderConfig{URL: server.URL}}
+
+	streams, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if len(streams) != 1 {
+		t.Fatalf("got %d streams, want 1: %v", len(streams), streams)
+	}
+
+	if got, want := streams["channel_one"], "http://example.com/one.m3u8"; got != want {
+		t.Errorf("streams[channel_one] = %q, want %q", got, want)
+	}
+
+	if _, ok := streams["channel_two"]; ok {
+		t.Error("channel without a <url> should be skipped")
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// reloadSubscribers fans out a notification to every subscriber every time a
+// Reload applies a new configuration. Sends are non-blocking: a subscriber
+// that isn't reading just misses the notification instead of stalling the
+// reload.
+type reloadSubscribers struct {
+	mu   sync.Mutex
+	subs []chan struct{}
+}
+
+func (r *reloadSubscribers) subscribe() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	r.subs = append(r.subs, ch)
+	return ch
+}
+
+func (r *reloadSubscribers) notify() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ch := range r.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a value every time Reload
+// applies a new configuration.
+func (s *Server) Subscribe() <-chan struct{} {
+	return s.reloadSubs.subscribe()
+}
+
+// Reload re-validates and re-applies the subset of configuration that is
+// safe to change without a restart: streams, stream-providers, hls-proxy,
+// vod.video-profiles and enigma2. It is safe to call concurrently, and is
+// wired up both to viper.WatchConfig and to the POST /api/reload endpoint.
+func (s *Server) Reload() error {
+	var videoProfiles map[string]VideoProfile
+	if err := viper.UnmarshalKey("vod.video-profiles", &videoProfiles); err != nil {
+		return fmt.Errorf("reload vod.video-profiles: %v", err)
+	}
+	if len(videoProfiles) == 0 {
+		return fmt.Errorf("reload: specify at least one VOD video profile")
+	}
+
+	var enigma2 ENIGMA2
+	if err := viper.UnmarshalKey("enigma2", &enigma2); err != nil {
+		return fmt.Errorf("reload enigma2: %v", err)
+	}
+
+	var providers []StreamProviderConfig
+	if err := viper.UnmarshalKey("stream-providers", &providers); err != nil {
+		return fmt.Errorf("reload stream-providers: %v", err)
+	}
+
+	for i := range providers {
+		if err := providers[i].decryptSecrets(); err != nil {
+			return fmt.Errorf("reload stream-providers: %v", err)
+		}
+	}
+
+	streams := viper.GetStringMapString("streams")
+
+	fetched, err := fetchConfiguredStreams(enigma2, providers)
+	if err != nil {
+		return fmt.Errorf("reload stream-providers: %v", err)
+	}
+	for name, stream := range fetched {
+		streams[name] = stream
+	}
+
+	hlsProxy := viper.GetStringMapString("hls-proxy")
+	for name, target := range hlsProxy {
+		decrypted, err := decryptIfNeeded(target)
+		if err != nil {
+			return fmt.Errorf("reload hls-proxy: %v", err)
+		}
+		hlsProxy[name] = decrypted
+	}
+
+	s.mu.Lock()
+	oldStreams := s.Streams
+	s.Streams = streams
+	s.Vod.VideoProfiles = videoProfiles
+	s.HlsProxy = hlsProxy
+	s.Enigma2 = enigma2
+	s.StreamProviders = providers
+	// streams is now s.Streams, which refresh loops write to under mu, so
+	// the diff is logged before releasing it.
+	logStreamDiff(oldStreams, streams)
+	s.mu.Unlock()
+
+	// Stop whatever provider refresh loops were running and start fresh
+	// ones, so a provider removed from stream-providers actually stops
+	// being polled, and one with rotated credentials stops using the old
+	// ones, instead of the original goroutines running on indefinitely.
+	if err := s.startStreamProviders(providers); err != nil {
+		return fmt.Errorf("reload stream-providers: %v", err)
+	}
+
+	s.reloadSubs.notify()
+
+	return nil
+}
+
+// logStreamDiff logs which streams were added, changed or removed by a
+// reload, so operators can tell what actually happened without diffing the
+// config file by hand.
+func logStreamDiff(old, updated map[string]string) {
+	for name, stream := range updated {
+		previous, existed := old[name]
+		switch {
+		case !existed:
+			log.Info().Str("stream", name).Msg("config reload: stream added")
+		case previous != stream:
+			log.Info().Str("stream", name).Msg("config reload: stream changed")
+		}
+	}
+
+	for name := range old {
+		if _, stillExists := updated[name]; !stillExists {
+			log.Info().Str("stream", name).Msg("config reload: stream removed")
+		}
+	}
+}
+
+// ReloadHandler exposes Reload as an admin HTTP endpoint, meant to be
+// mounted at POST /api/reload.
+func (s *Server) ReloadHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := s.Reload(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "reloaded"})
+	}
+}
+
+// WatchConfig watches the config file for changes and calls onChange
+// (typically Server.Reload) whenever it is rewritten.
+func (Root) WatchConfig(onChange func()) {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		onChange()
+	})
+	viper.WatchConfig()
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSecretsCommand builds the `transcode secrets` command tree, used to
+// produce and rotate enc:... ciphertexts for the key source configured
+// under secrets.key-source.
+func NewSecretsCommand() *cobra.Command {
+	var keySource string
+
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Encrypt or decrypt config secrets",
+	}
+	cmd.PersistentFlags().StringVar(&keySource, "key-source", "", "secrets key source (env:VAR or file:/path)")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt a value for use in the config as enc:...",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := loadSecretsKey(keySource)
+			if err != nil {
+				return err
+			}
+
+			ciphertext, err := EncryptSecret(key, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(ciphertext)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "decrypt [value]",
+		Short: "Decrypt an enc:... value, to verify a rotation before deploying it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := loadSecretsKey(keySource)
+			if err != nil {
+				return err
+			}
+
+			plaintext, err := DecryptSecret(key, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(plaintext)
+			return nil
+		},
+	})
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package config
+
+import "testing"
+
+func TestEncryptDecryptSecretRoundTrip(t *testing.T) {
+	key := deriveKey("test-passphrase")
+
+	for _, plaintext := range []string{"", "hello", "a longer secret value with spaces & punctuation!"} {
+		ciphertext, err := EncryptSecret(key, plaintext)
+		if err != nil {
+			t.Fatalf("EncryptSecret(%q): %v", plaintext, err)
+		}
+
+		if len(ciphertext) < len(secretPrefix) || ciphertext[:len(secretPrefix)] != secretPrefix {
+			t.Fatalf("EncryptSecret(%q) = %q, want %q prefix", plaintext, ciphertext, secretPrefix)
+		}
+
+		decrypted, err := DecryptSecret(key, ciphertext)
+		if err != nil {
+			t.Fatalf("DecryptSecret(%q): %v", ciphertext, err)
+		}
+
+		if decrypted != plaintext {
+			t.Fatalf("round trip mismatch: got %q, want %q", decrypted, plaintext)
+		}
+	}
+}
+
+func TestEncryptSecretUsesRandomNonce(t *testing.T) {
+	key := deriveKey("test-passphrase")
+
+	first, err := EncryptSecret(key, "same plaintext")
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+
+	second, err := EncryptSecret(key, "same plaintext")
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+
+	if first == second {
+		t.Fatal("EncryptSecret produced identical ciphertexts for the same plaintext")
+	}
+}
+
+func TestDecryptSecretWrongKeyFails(t *testing.T) {
+	ciphertext, err := EncryptSecret(deriveKey("key-a"), "secret")
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+
+	if _, err := DecryptSecret(deriveKey("key-b"), ciphertext); err == nil {
+		t.Fatal("DecryptSecret should fail when given the wrong key")
+	}
+}
+
+func TestDecryptIfNeededLeavesPlaintextAlone(t *testing.T) {
+	got, err := decryptIfNeeded("plain-value")
+	if err != nil {
+		t.Fatalf("decryptIfNeeded(plain): %v", err)
+	}
+	if got != "plain-value" {
+		t.Fatalf("decryptIfNeeded(plain) = %q, want unchanged", got)
+	}
+}
+
+func TestDecryptIfNeededFailsLoudlyWithoutKeySource(t *testing.T) {
+	previous := secretsKey
+	secretsKey = nil
+	defer func() { secretsKey = previous }()
+
+	if _, err := decryptIfNeeded("enc:deadbeef"); err == nil {
+		t.Fatal("decryptIfNeeded should error on an enc: value with no key source configured")
+	}
+}